@@ -27,6 +27,7 @@ type ImportGraph struct {
 	includeStd bool
 	todo       []string
 	added      []string
+	roots      []string
 }
 
 type matcher struct {
@@ -54,9 +55,11 @@ func (mr matcher) Match(pn string) bool {
 
 func doDAG() {
 	includeStd := flag.Bool("std", false, "include packages in stdlib")
-	outputFormat := flag.String("f", "flat", "output format: flat, text, dot, or svg. 'dot' and 'svg' requires the dot program")
+	outputFormat := flag.String("f", "flat", "output format: flat, text, dot, svg, or modinfo. 'dot' and 'svg' requires the dot program")
 	svgViewer := flag.String("svgviewer", "xdg-open", "svg viewer")
 	matches := flag.String("m", "", "show only packages that match this regexp")
+	collapse := flag.Bool("collapse", false, "collapse each non-trivial strongly-connected component into a single cluster node (dot, svg, text)")
+	cycles := flag.Bool("cycles", false, "print only import cycles, instead of the full graph")
 	cli.ParseFlag()
 
 	wd, err := os.Getwd()
@@ -69,13 +72,20 @@ func doDAG() {
 
 	mr := newMatcher(*matches)
 
+	if *cycles {
+		ig.WriteCycles(os.Stdout, mr)
+		return
+	}
+
 	switch *outputFormat {
 	case "dot":
-		ig.WriteDot(os.Stdout, mr)
+		ig.WriteDot(os.Stdout, mr, *collapse)
 	case "svg":
-		ig.ShowGraph(*svgViewer, mr)
+		ig.ShowGraph(*svgViewer, mr, *collapse)
 	case "text":
-		ig.WriteText(os.Stdout, mr)
+		ig.WriteText(os.Stdout, mr, *collapse)
+	case "modinfo":
+		ig.WriteModInfo(os.Stdout, mr)
 	default:
 		ig.WriteFlat(os.Stdout, mr)
 	}
@@ -172,14 +182,36 @@ func (ig *ImportGraph) list(args []string) {
 		pkgs = append(pkgs, p)
 	}
 	ig.todo = pkgs
+	ig.roots = pkgs
 }
 
-func (ig *ImportGraph) WriteText(w io.Writer, mr matcher) {
+func (ig *ImportGraph) WriteText(w io.Writer, mr matcher, collapse bool) {
+	var compID map[string]int
+	var comps [][]string
+	if collapse {
+		compID, comps = ig.sccOf()
+	}
+	printed := map[int]bool{}
+
 	for _, pn := range ig.added {
 		if !mr.Match(pn) {
 			continue
 		}
 
+		if collapse {
+			if id, ok := compID[pn]; ok && len(comps[id]) > 1 {
+				if printed[id] {
+					continue
+				}
+				printed[id] = true
+				fmt.Fprintf(w, "SCC%d (%d members):\n", id, len(comps[id]))
+				for _, m := range comps[id] {
+					fmt.Fprintf(w, "    %s\n", m)
+				}
+				continue
+			}
+		}
+
 		var filtered []string
 		for _, pi := range ig.Imports[pn] {
 			if !ig.includeStd {
@@ -218,14 +250,31 @@ func (ig *ImportGraph) WriteFlat(w io.Writer, mr matcher) {
 	}
 }
 
-func (ig *ImportGraph) WriteDot(w io.Writer, mr matcher) {
-	nodes := make(map[string]int)
+func (ig *ImportGraph) WriteDot(w io.Writer, mr matcher, collapse bool) {
+	nodeIdx := make(map[string]int, len(ig.added))
 	for i, pn := range ig.added {
-		nodes[pn] = i
+		nodeIdx[pn] = i
 	}
 
+	var compID map[string]int
+	var comps [][]string
+	if collapse {
+		compID, comps = ig.sccOf()
+	}
+
+	nodeID := func(pn string) string {
+		if collapse {
+			if id, ok := compID[pn]; ok && len(comps[id]) > 1 {
+				return fmt.Sprintf("scc%d", id)
+			}
+		}
+		return fmt.Sprintf("n%d", nodeIdx[pn])
+	}
+
+	printed := map[string]bool{}
+
 	fmt.Fprintf(w, "digraph pkgdag {\n")
-	for i, pn := range ig.added {
+	for _, pn := range ig.added {
 		if !mr.Match(pn) {
 			continue
 		}
@@ -246,18 +295,40 @@ func (ig *ImportGraph) WriteDot(w io.Writer, mr matcher) {
 			continue
 		}
 
-		if pv := ig.ImportPaths[pn]; strings.Contains(pv, "/vendor/") {
-			fmt.Fprintf(w, "    %d [label=\"%s\",style=filled];\n", i, pn)
-		} else {
-			fmt.Fprintf(w, "    %d [label=\"%s\"];\n", i, pn)
+		id := nodeID(pn)
+		if !printed[id] {
+			printed[id] = true
+			if members, ok := collapsedMembers(compID, comps, pn, collapse); ok {
+				fmt.Fprintf(w, "    %s [shape=box,style=filled,label=\"%s\"];\n", id, strings.Join(members, "\\n"))
+			} else if pv := ig.ImportPaths[pn]; strings.Contains(pv, "/vendor/") {
+				fmt.Fprintf(w, "    %s [label=\"%s\",style=filled];\n", id, pn)
+			} else {
+				fmt.Fprintf(w, "    %s [label=\"%s\"];\n", id, pn)
+			}
 		}
+
 		for _, pi := range filtered {
-			fmt.Fprintf(w, "    %d -> %d;\n", i, nodes[pi])
+			toID := nodeID(pi)
+			if toID == id {
+				continue // collapsed self-edge within the same SCC cluster
+			}
+			fmt.Fprintf(w, "    %s -> %s;\n", id, toID)
 		}
 	}
 	fmt.Fprintf(w, "}\n")
 }
 
+func collapsedMembers(compID map[string]int, comps [][]string, pn string, collapse bool) ([]string, bool) {
+	if !collapse {
+		return nil, false
+	}
+	id, ok := compID[pn]
+	if !ok || len(comps[id]) <= 1 {
+		return nil, false
+	}
+	return comps[id], true
+}
+
 func run(name string, stdin io.Reader, args ...string) {
 	c := exec.Command(name, args...)
 	c.Stdin = stdin
@@ -268,9 +339,9 @@ func run(name string, stdin io.Reader, args ...string) {
 	}
 }
 
-func (ig *ImportGraph) ShowGraph(svgViewer string, mr matcher) {
+func (ig *ImportGraph) ShowGraph(svgViewer string, mr matcher, collapse bool) {
 	var buf bytes.Buffer
-	ig.WriteDot(&buf, mr)
+	ig.WriteDot(&buf, mr, collapse)
 
 	f, err := ioutil.TempFile("", "pkgdag-")
 	if err != nil {
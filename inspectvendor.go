@@ -49,6 +49,14 @@ type Spec struct {
 	// Package represents a collection of vendor packages that have been copied
 	// locally. Each entry represents a single Go package.
 	Pkgs []*PkgSpec `json:"package"`
+
+	// Modules is true when this Spec was built from go.mod/go.sum/go list
+	// rather than vendor/vendor.json.
+	Modules bool `json:"-"`
+
+	// MainModule is the module path declared by go.mod. Only set when
+	// Modules is true.
+	MainModule string `json:"-"`
 }
 
 func (ps *PkgSpec) String() string {
@@ -73,13 +81,14 @@ func main() {
 	cli.Define("dir", doDirect)
 	cli.Define("indir", doIndirect)
 	cli.Define("showversion", doShowVersion)
+	cli.Define("why", doWhy)
 	cli.Main()
 }
 
 func doList() {
 	cli.ParseFlag(initCommon)
 
-	spec := readSpec(*repo, "")
+	spec := loadSpec(*repo)
 
 	for _, p := range spec.Pkgs {
 		fmt.Fprintf(w, "%s\t%s\n", p.Path, p.Revision)
@@ -89,7 +98,7 @@ func doList() {
 func doDirect() {
 	cli.ParseFlag(initCommon)
 
-	spec := readSpec(*repo, "")
+	spec := loadSpec(*repo)
 
 	for _, p := range spec.Pkgs {
 		if p.Origin != "" {
@@ -102,7 +111,7 @@ func doDirect() {
 func doIndirect() {
 	cli.ParseFlag(initCommon)
 
-	spec := readSpec(*repo, "")
+	spec := loadSpec(*repo)
 	for _, p := range spec.Pkgs {
 		if p.Origin == "" {
 			continue
@@ -128,7 +137,7 @@ func doShowVersion() {
 	pkgToCheck := flag.String("p", "", "check this package only")
 	cli.ParseFlag(initCommon)
 
-	spec := readSpec(*repo, "")
+	spec := loadSpec(*repo)
 	for _, p := range spec.Pkgs {
 		if *pkgToCheck != "" && *pkgToCheck != p.Path {
 			continue
@@ -139,7 +148,12 @@ func doShowVersion() {
 			continue
 		}
 
-		vos := findTrueVersion(p, *maxDepth)
+		var vos []VersionOrigin
+		if spec.Modules {
+			vos = findTrueVersionModule(*repo, p, *maxDepth)
+		} else {
+			vos = findTrueVersion(p, *maxDepth)
+		}
 		lgf("true version of pkg=p.Path: %v", vos)
 		ver := "UNKNOWN"
 		if len(vos) > 1 {
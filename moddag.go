@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PackageModuleInfo is the machine-readable record emitted by
+// ImportGraph.WriteModInfo for each scanned package: the import path,
+// its enclosing module and version, the module's go.sum hash when
+// known, and the modules its own direct imports belong to.
+type PackageModuleInfo struct {
+	ImportPath string          `json:"importPath"`
+	Module     string          `json:"module,omitempty"`
+	Version    string          `json:"version,omitempty"`
+	Hash       string          `json:"hash,omitempty"`
+	Deps       []ModuleVersion `json:"deps,omitempty"`
+}
+
+type ModuleVersion struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+}
+
+// pkgModule is the module and version that own a single package.
+type pkgModule struct {
+	Module  string
+	Version string
+}
+
+// WriteModInfo prints one PackageModuleInfo JSON record per scanned
+// package that matches mr, one per line, so downstream tooling can
+// consume a scan without re-parsing text output.
+func (ig *ImportGraph) WriteModInfo(w io.Writer, mr matcher) {
+	sums := parseGoSum(filepath.Join(ig.srcDir, "go.sum"))
+	modules := ig.moduleIndex()
+
+	enc := json.NewEncoder(w)
+	for _, pn := range ig.added {
+		if !mr.Match(pn) {
+			continue
+		}
+
+		pm := modules[pn]
+		info := PackageModuleInfo{
+			ImportPath: pn,
+			Module:     pm.Module,
+			Version:    pm.Version,
+			Hash:       sums[pm.Module+"@"+pm.Version],
+		}
+
+		seen := map[string]bool{}
+		for _, pi := range ig.Imports[pn] {
+			dm := modules[pi]
+			if dm.Module == "" || dm.Module == pm.Module || seen[dm.Module] {
+				continue
+			}
+			seen[dm.Module] = true
+			info.Deps = append(info.Deps, ModuleVersion{Module: dm.Module, Version: dm.Version})
+		}
+
+		if err := enc.Encode(info); err != nil {
+			log.Fatalf("encode modinfo for pkg=%s err=%v", pn, err)
+		}
+	}
+}
+
+// moduleIndex resolves the module and version of every package touched
+// by the scan -- ig.added plus everything each of them imports -- in a
+// single pass: one vendor/vendor.json read when ig.srcDir is vendored,
+// or one batched `go list -json` call for module-based repos, rather
+// than re-reading vendor.json or spawning `go list` once per package.
+func (ig *ImportGraph) moduleIndex() map[string]pkgModule {
+	idx := make(map[string]pkgModule)
+
+	vendorRevisions := map[string]string{}
+	if _, err := os.Stat(filepath.Join(ig.srcDir, "vendor", "vendor.json")); err == nil {
+		if spec := readSpec(ig.srcDir, ""); spec != nil {
+			for _, p := range spec.Pkgs {
+				vendorRevisions[p.Path] = p.Revision
+			}
+		}
+	}
+
+	want := map[string]bool{}
+	for _, pn := range ig.added {
+		want[pn] = true
+		for _, pi := range ig.Imports[pn] {
+			want[pi] = true
+		}
+	}
+
+	var nonVendored []string
+	for pn := range want {
+		if i := strings.Index(pn, "/vendor/"); i >= 0 {
+			vendorPath := pn[i+len("/vendor/"):]
+			idx[pn] = pkgModule{Module: vendorModulePath(vendorPath), Version: vendorRevisions[vendorPath]}
+			continue
+		}
+		nonVendored = append(nonVendored, pn)
+	}
+
+	for pn, m := range goListModules(ig.srcDir, nonVendored) {
+		if m == nil {
+			continue
+		}
+		idx[pn] = pkgModule{Module: m.Path, Version: m.Version}
+	}
+	return idx
+}
+
+// vendorModulePath approximates a module path from a vendored import
+// path, taking the host/org/repo prefix a Go module path conventionally
+// uses.
+func vendorModulePath(importPath string) string {
+	parts := strings.SplitN(importPath, "/", 4)
+	if len(parts) < 3 {
+		return importPath
+	}
+	return strings.Join(parts[:3], "/")
+}
+
+// goListModules runs a single `go list -json` over all of pkgs and
+// returns each package's enclosing module, keyed by import path. A
+// package with no module (e.g. stdlib) is omitted.
+func goListModules(dir string, pkgs []string) map[string]*ModuleInfo {
+	out := make(map[string]*ModuleInfo, len(pkgs))
+	if len(pkgs) == 0 {
+		return out
+	}
+
+	var buf bytes.Buffer
+	args := append([]string{"list", "-json"}, pkgs...)
+	c := exec.Command("go", args...)
+	c.Dir = dir
+	c.Stdout = &buf
+	if err := c.Run(); err != nil {
+		return out
+	}
+
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var pkg struct {
+			ImportPath string
+			Module     *ModuleInfo
+		}
+		if err := dec.Decode(&pkg); err != nil {
+			log.Printf("decode go list -json output in dir=%s err=%v", dir, err)
+			break
+		}
+		if pkg.Module != nil {
+			out[pkg.ImportPath] = pkg.Module
+		}
+	}
+	return out
+}
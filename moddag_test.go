@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModuleIndexResolvesVendoredPackageFromVendorJSON(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "vendor"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := &Spec{Pkgs: []*PkgSpec{
+		{Path: "github.com/foo/bar/sub", Revision: "v1.2.3"},
+	}}
+	b, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "vendor", "vendor.json"), b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	const pkg = "example.com/app/vendor/github.com/foo/bar/sub"
+	ig := &ImportGraph{
+		srcDir:  dir,
+		Imports: map[string][]string{pkg: nil},
+		added:   []string{pkg},
+	}
+
+	got := ig.moduleIndex()[pkg]
+	want := pkgModule{Module: "github.com/foo/bar", Version: "v1.2.3"}
+	if got != want {
+		t.Fatalf("moduleIndex()[%s] = %+v, want %+v", pkg, got, want)
+	}
+}
+
+func TestGoListModulesEmptyInputSkipsExec(t *testing.T) {
+	if got := goListModules(".", nil); len(got) != 0 {
+		t.Fatalf("goListModules(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModuleInfo mirrors the fields of `go list -m -json` that we care about.
+type ModuleInfo struct {
+	Path     string
+	Version  string
+	Main     bool
+	Indirect bool
+}
+
+var requireLineRe = regexp.MustCompile(`^\s*([^\s]+)\s+([^\s]+)(\s+//\s*indirect)?\s*$`)
+
+// isModuleRepo reports whether dir is the root of a go.mod-based repo, as
+// opposed to one vendored with govendor's vendor.json.
+func isModuleRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// parseGoMod reads the go.mod file fn and returns the main module path and
+// its require block. It understands both single-line and block ("require
+// (...)") forms.
+func parseGoMod(fn string) (mainModule string, requires []GoModRequire) {
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		log.Fatalf("read go.mod=%s err=%v", fn, err)
+	}
+
+	inBlock := false
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "module "):
+			mainModule = strings.TrimSpace(strings.TrimPrefix(trimmed, "module "))
+		case trimmed == "require (":
+			inBlock = true
+		case inBlock && trimmed == ")":
+			inBlock = false
+		case inBlock:
+			if r, ok := parseRequireLine(trimmed); ok {
+				requires = append(requires, r)
+			}
+		case strings.HasPrefix(trimmed, "require "):
+			if r, ok := parseRequireLine(strings.TrimPrefix(trimmed, "require ")); ok {
+				requires = append(requires, r)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatalf("scan go.mod=%s err=%v", fn, err)
+	}
+	return mainModule, requires
+}
+
+type GoModRequire struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+func parseRequireLine(line string) (GoModRequire, bool) {
+	m := requireLineRe.FindStringSubmatch(line)
+	if m == nil {
+		return GoModRequire{}, false
+	}
+	return GoModRequire{Path: m[1], Version: m[2], Indirect: m[3] != ""}, true
+}
+
+// parseGoSum reads go.sum and returns the h1: hash of each module@version,
+// keyed as "path@version".
+func parseGoSum(fn string) map[string]string {
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		log.Fatalf("read go.sum=%s err=%v", fn, err)
+	}
+
+	hashes := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		path, ver, hash := fields[0], fields[1], fields[2]
+		if strings.HasSuffix(ver, "/go.mod") {
+			continue
+		}
+		hashes[path+"@"+ver] = hash
+	}
+	return hashes
+}
+
+// listModules runs `go list -m -json all` in dir and returns the full
+// module graph as seen from the main module.
+func listModules(dir string) []ModuleInfo {
+	var buf bytes.Buffer
+	c := exec.Command("go", "list", "-m", "-json", "all")
+	c.Dir = dir
+	c.Stderr = os.Stderr
+	c.Stdout = &buf
+	if err := c.Run(); err != nil {
+		log.Fatalf("go list -m -json all in dir=%s err=%v", dir, err)
+	}
+
+	var mods []ModuleInfo
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var m ModuleInfo
+		if err := dec.Decode(&m); err != nil {
+			log.Fatalf("decode module in dir=%s err=%v", dir, err)
+		}
+		mods = append(mods, m)
+	}
+	return mods
+}
+
+// readModSpec builds a Spec for a go.mod-based repo, so the existing
+// doList/doDirect/doIndirect/doShowVersion commands can work on it the
+// same way they work on a vendor.json-based one. A package is direct if
+// it is listed, without "// indirect", in the main go.mod's require
+// block; everything else (transitive deps, or deps marked "// indirect")
+// is indirect, with Origin set to the main module path to record who
+// pulled it in.
+func readModSpec(dir string) *Spec {
+	mainModule, requires := parseGoMod(filepath.Join(dir, "go.mod"))
+
+	direct := make(map[string]bool)
+	for _, r := range requires {
+		direct[r.Path] = !r.Indirect
+	}
+
+	spec := &Spec{Modules: true, MainModule: mainModule}
+	for _, m := range listModules(dir) {
+		if m.Main {
+			continue
+		}
+		ps := &PkgSpec{Path: m.Path, Revision: m.Version}
+		if !direct[m.Path] {
+			ps.Origin = mainModule
+		}
+		spec.Pkgs = append(spec.Pkgs, ps)
+	}
+	return spec
+}
+
+// loadSpec auto-detects which of vendor.json or go.mod the repo at dir
+// uses and reads its dependency spec accordingly.
+func loadSpec(dir string) *Spec {
+	if isModuleRepo(dir) {
+		return readModSpec(dir)
+	}
+	return readSpec(dir, "")
+}
+
+// Requirer is one edge of `go mod graph` pointing at a target module: a
+// module that requires the target, together with both the requiring
+// module's own published version (needed to fetch its real go.mod) and
+// the version it requires of the target (needed to pick the strictest
+// requirer for MVS).
+type Requirer struct {
+	Path            string
+	OwnVersion      string
+	RequiredVersion string
+}
+
+// requirerFetcher abstracts the two external lookups the MVS walk in
+// walkTrueVersion needs, so the walk itself can be unit tested without
+// shelling out to `go mod graph` / `go mod download`.
+type requirerFetcher interface {
+	// requirersOf returns the modules that directly require modPath.
+	requirersOf(modPath string) []Requirer
+	// requiresOf returns the require block of modPath@version's own
+	// go.mod, or nil if it could not be loaded.
+	requiresOf(modPath, version string) []GoModRequire
+}
+
+type goCommandFetcher struct {
+	dir string
+}
+
+func (f goCommandFetcher) requirersOf(modPath string) []Requirer {
+	return requirersOf(f.dir, modPath)
+}
+
+func (f goCommandFetcher) requiresOf(modPath, version string) []GoModRequire {
+	modFile := fetchGoMod(modPath, version)
+	if modFile == "" {
+		return nil
+	}
+	_, requires := parseGoMod(modFile)
+	return requires
+}
+
+// findTrueVersionModule walks backward from p.Path through the module
+// graph, re-deriving the resolved version at each hop by loading the
+// requiring module's own go.mod (fetched via `go mod download -json`)
+// and picking the maximum version it requires for the current target --
+// one step of Minimum Version Selection. We recurse only while that
+// step changes the resolved version, and stop at a fixed point or at
+// maxDepth. The returned chain starts with p's own revision; each later
+// entry records one hop as the requiring module's path (Origin) and the
+// version it requires of that hop's target (Version). The requirer's own
+// version and the target's module path are not kept in the entries; they
+// appear only in the -v log line of each hop.
+func findTrueVersionModule(dir string, p *PkgSpec, maxDepth int) []VersionOrigin {
+	return walkTrueVersion(goCommandFetcher{dir: dir}, p, maxDepth)
+}
+
+// walkTrueVersion is the fetcher-agnostic core of findTrueVersionModule.
+// Each hop climbs one rung further from p.Path: it finds who requires
+// the current target, loads that requirer's own go.mod, and checks
+// whether it pins a stricter version, continuing the walk from the
+// requirer itself so successive hops keep climbing the graph instead of
+// re-examining the same edge.
+func walkTrueVersion(f requirerFetcher, p *PkgSpec, maxDepth int) []VersionOrigin {
+	out := []VersionOrigin{{Version: p.Revision, Origin: p.Revision}}
+
+	target, resolved := p.Path, p.Revision
+	for depth := 0; depth < maxDepth; depth++ {
+		requirers := f.requirersOf(target)
+		if len(requirers) == 0 {
+			lgf("no requirer of module=%s found in go mod graph", target)
+			break
+		}
+
+		var via Requirer
+		for _, r := range requirers {
+			if via.Path == "" || compareVersions(r.RequiredVersion, via.RequiredVersion) > 0 {
+				via = r
+			}
+		}
+
+		requires := f.requiresOf(via.Path, via.OwnVersion)
+		if requires == nil {
+			lgf("could not fetch go.mod of module=%s@%s", via.Path, via.OwnVersion)
+			break
+		}
+
+		var maxVer string
+		for _, r := range requires {
+			if r.Path == target && (maxVer == "" || compareVersions(r.Version, maxVer) > 0) {
+				maxVer = r.Version
+			}
+		}
+		if maxVer == "" || maxVer == resolved {
+			lgf("mvs fixed point reached for module=%s at version=%s", target, resolved)
+			break
+		}
+
+		lgf("mvs hop repo=%s@%s -> module=%s@%s", via.Path, via.OwnVersion, target, maxVer)
+		out = append(out, VersionOrigin{Version: maxVer, Origin: via.Path})
+		resolved = via.OwnVersion
+		target = via.Path
+	}
+	return out
+}
+
+// fetchGoMod downloads modPath@version into the module cache and returns
+// the path to its go.mod file, or "" if it could not be fetched.
+func fetchGoMod(modPath, version string) string {
+	var buf bytes.Buffer
+	c := exec.Command("go", "mod", "download", "-json", modPath+"@"+version)
+	c.Stderr = os.Stderr
+	c.Stdout = &buf
+	if err := c.Run(); err != nil {
+		lgf("go mod download %s@%s err=%v", modPath, version, err)
+		return ""
+	}
+
+	var info struct {
+		GoMod string
+	}
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		lgf("decode go mod download output for %s@%s err=%v", modPath, version, err)
+		return ""
+	}
+	return info.GoMod
+}
+
+// compareVersions compares two semver-ish "vX.Y.Z[-pre]" version strings,
+// returning <0, 0, or >0 as a < b, a == b, or a > b. Unparsable
+// components fall back to a plain string comparison. Equal major.minor.patch
+// is broken by the prerelease suffix: a version with no prerelease outranks
+// one with one (pseudo-versions included, since a pseudo-version is encoded
+// as a prerelease), and two prereleases compare lexicographically, which
+// orders pseudo-versions chronologically since they embed an RFC3339-ish
+// timestamp.
+func compareVersions(a, b string) int {
+	pa, prea, oka := parseSemver(a)
+	pb, preb, okb := parseSemver(b)
+	if !oka || !okb {
+		return strings.Compare(a, b)
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case prea == "" && preb == "":
+		return 0
+	case prea == "":
+		return 1
+	case preb == "":
+		return -1
+	default:
+		return strings.Compare(prea, preb)
+	}
+}
+
+func parseSemver(v string) (core [3]int, prerelease string, ok bool) {
+	v = strings.TrimPrefix(v, "v")
+	v = strings.SplitN(v, "+", 2)[0]
+	verCore := v
+	if i := strings.Index(v, "-"); i >= 0 {
+		verCore, prerelease = v[:i], v[i+1:]
+	}
+	parts := strings.SplitN(verCore, ".", 3)
+	if len(parts) != 3 {
+		return core, "", false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return core, "", false
+		}
+		core[i] = n
+	}
+	return core, prerelease, true
+}
+
+// requirersOf runs `go mod graph` in dir and returns the modules that
+// directly require modPath, recording both their own published version
+// (needed to fetch their real go.mod) and the version of modPath they
+// require.
+func requirersOf(dir, modPath string) []Requirer {
+	var buf bytes.Buffer
+	c := exec.Command("go", "mod", "graph")
+	c.Dir = dir
+	c.Stderr = os.Stderr
+	c.Stdout = &buf
+	if err := c.Run(); err != nil {
+		log.Fatalf("go mod graph in dir=%s err=%v", dir, err)
+	}
+	return parseModGraph(buf.Bytes(), modPath)
+}
+
+// parseModGraph parses `go mod graph` output (each line "from@version
+// to@version") and returns the requirers of modPath. The main module, which
+// go mod graph lists without a version, is not among them.
+func parseModGraph(b []byte, modPath string) []Requirer {
+	var out []Requirer
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		to := fields[1]
+		if !strings.HasPrefix(to, modPath+"@") {
+			continue
+		}
+		from := strings.SplitN(fields[0], "@", 2)
+		if len(from) != 2 {
+			continue
+		}
+		out = append(out, Requirer{Path: from[0], OwnVersion: from[1], RequiredVersion: to[len(modPath)+1:]})
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIsModuleRepo(t *testing.T) {
+	dir := t.TempDir()
+	if isModuleRepo(dir) {
+		t.Fatalf("isModuleRepo(%s) = true before go.mod exists", dir)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !isModuleRepo(dir) {
+		t.Fatalf("isModuleRepo(%s) = false after go.mod exists", dir)
+	}
+}
+
+func TestParseGoModBlockAndSingleLineRequires(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "go.mod")
+	content := `module example.com/app
+
+go 1.12
+
+require example.com/single v1.0.0
+
+require (
+	example.com/direct v1.2.3
+	example.com/indirect v2.0.0 // indirect
+)
+`
+	if err := os.WriteFile(fn, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mainModule, requires := parseGoMod(fn)
+	if mainModule != "example.com/app" {
+		t.Fatalf("mainModule = %q, want example.com/app", mainModule)
+	}
+
+	want := []GoModRequire{
+		{Path: "example.com/single", Version: "v1.0.0"},
+		{Path: "example.com/direct", Version: "v1.2.3"},
+		{Path: "example.com/indirect", Version: "v2.0.0", Indirect: true},
+	}
+	if !reflect.DeepEqual(requires, want) {
+		t.Fatalf("requires = %v, want %v", requires, want)
+	}
+}
+
+func TestParseGoSum(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "go.sum")
+	content := "example.com/foo v1.0.0 h1:abc=\n" +
+		"example.com/foo v1.0.0/go.mod h1:def=\n"
+	if err := os.WriteFile(fn, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hashes := parseGoSum(fn)
+	want := map[string]string{"example.com/foo@v1.0.0": "h1:abc="}
+	if !reflect.DeepEqual(hashes, want) {
+		t.Fatalf("parseGoSum() = %v, want %v", hashes, want)
+	}
+}
+
+func TestParseGoSumMissingFile(t *testing.T) {
+	if got := parseGoSum(filepath.Join(t.TempDir(), "go.sum")); got != nil {
+		t.Fatalf("parseGoSum(missing) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fakeFetcher struct {
+	requirers map[string][]Requirer
+	requires  map[string][]GoModRequire
+}
+
+func (f fakeFetcher) requirersOf(modPath string) []Requirer {
+	return f.requirers[modPath]
+}
+
+func (f fakeFetcher) requiresOf(modPath, version string) []GoModRequire {
+	return f.requires[modPath+"@"+version]
+}
+
+func TestWalkTrueVersionMultiHop(t *testing.T) {
+	// leaf is required by mid@v2.3.1 (which itself requires leaf@v1.0.0
+	// per the graph edge), but mid's own go.mod actually wants
+	// leaf@v1.1.0. mid is in turn required by top@v1.4.0 (which
+	// requires mid@v1.1.0 per the graph edge), but top's own go.mod
+	// wants mid@v1.2.0. The walk should climb both hops, fetching each
+	// requirer at its *own* version, not at the version it demands of
+	// the target.
+	f := fakeFetcher{
+		requirers: map[string][]Requirer{
+			"example.com/leaf": {{Path: "example.com/mid", OwnVersion: "v2.3.1", RequiredVersion: "v1.0.0"}},
+			"example.com/mid":  {{Path: "example.com/top", OwnVersion: "v1.4.0", RequiredVersion: "v1.1.0"}},
+		},
+		requires: map[string][]GoModRequire{
+			"example.com/mid@v2.3.1": {{Path: "example.com/leaf", Version: "v1.1.0"}},
+			"example.com/top@v1.4.0": {{Path: "example.com/mid", Version: "v1.2.0"}},
+		},
+	}
+
+	p := &PkgSpec{Path: "example.com/leaf", Revision: "v1.0.0"}
+	got := walkTrueVersion(f, p, 5)
+
+	want := []VersionOrigin{
+		{Version: "v1.0.0", Origin: "v1.0.0"},
+		{Version: "v1.1.0", Origin: "example.com/mid"},
+		{Version: "v1.2.0", Origin: "example.com/top"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("walkTrueVersion() = %v, want %v", got, want)
+	}
+}
+
+func TestWalkTrueVersionFixedPoint(t *testing.T) {
+	// mid's go.mod requires leaf at the same version already recorded,
+	// so the walk must stop instead of looping.
+	f := fakeFetcher{
+		requirers: map[string][]Requirer{
+			"example.com/leaf": {{Path: "example.com/mid", OwnVersion: "v2.3.1", RequiredVersion: "v1.0.0"}},
+		},
+		requires: map[string][]GoModRequire{
+			"example.com/mid@v2.3.1": {{Path: "example.com/leaf", Version: "v1.0.0"}},
+		},
+	}
+
+	p := &PkgSpec{Path: "example.com/leaf", Revision: "v1.0.0"}
+	got := walkTrueVersion(f, p, 5)
+
+	want := []VersionOrigin{{Version: "v1.0.0", Origin: "v1.0.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("walkTrueVersion() = %v, want %v", got, want)
+	}
+}
+
+func TestParseModGraphKeepsRequirersOwnVersion(t *testing.T) {
+	// Regression test for the exact scenario reported in review: the
+	// requirer's own published version (mid@v2.3.1) must be kept
+	// separate from the version it demands of the target (leaf@v1.0.0).
+	graph := "example.com/top@v1.1.0 example.com/mid@v2.3.1\n" +
+		"example.com/mid@v2.3.1 example.com/leaf@v1.0.0\n"
+
+	got := parseModGraph([]byte(graph), "example.com/leaf")
+	want := []Requirer{{Path: "example.com/mid", OwnVersion: "v2.3.1", RequiredVersion: "v1.0.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseModGraph() = %v, want %v", got, want)
+	}
+}
+
+func TestCompareVersionsPseudoVersion(t *testing.T) {
+	older := "v0.0.0-20200101000000-aaaaaaaaaaaa"
+	newer := "v0.0.0-20230101000000-bbbbbbbbbbbb"
+
+	if c := compareVersions(older, newer); c >= 0 {
+		t.Fatalf("compareVersions(%s, %s) = %d, want < 0", older, newer, c)
+	}
+	if c := compareVersions(newer, older); c <= 0 {
+		t.Fatalf("compareVersions(%s, %s) = %d, want > 0", newer, older, c)
+	}
+	if c := compareVersions(older, older); c != 0 {
+		t.Fatalf("compareVersions(%s, %s) = %d, want 0", older, older, c)
+	}
+	if c := compareVersions("v1.0.0", older); c <= 0 {
+		t.Fatalf("compareVersions(v1.0.0, %s) = %d, want > 0 (release outranks pseudo-version)", older, c)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// sccFinder runs Tarjan's strongly-connected-components algorithm over
+// ImportGraph.Imports.
+type sccFinder struct {
+	ig      *ImportGraph
+	index   map[string]int
+	low     map[string]int
+	onStack map[string]bool
+	stack   []string
+	counter int
+	sccs    [][]string
+}
+
+// SCCs returns the strongly-connected components of the scanned import
+// graph, each as a list of import paths. A component of size 1 whose
+// package does not import itself is not a cycle; use Cycles to filter
+// those out.
+func (ig *ImportGraph) SCCs() [][]string {
+	f := &sccFinder{
+		ig:      ig,
+		index:   map[string]int{},
+		low:     map[string]int{},
+		onStack: map[string]bool{},
+	}
+	for _, pn := range ig.added {
+		if _, ok := f.index[pn]; !ok {
+			f.strongconnect(pn)
+		}
+	}
+	return f.sccs
+}
+
+func (f *sccFinder) strongconnect(v string) {
+	f.index[v] = f.counter
+	f.low[v] = f.counter
+	f.counter++
+	f.stack = append(f.stack, v)
+	f.onStack[v] = true
+
+	for _, w := range f.ig.Imports[v] {
+		if _, ok := f.ig.Imports[w]; !ok {
+			continue // w was filtered out of the scan (e.g. stdlib)
+		}
+		if _, ok := f.index[w]; !ok {
+			f.strongconnect(w)
+			if f.low[w] < f.low[v] {
+				f.low[v] = f.low[w]
+			}
+		} else if f.onStack[w] {
+			if f.index[w] < f.low[v] {
+				f.low[v] = f.index[w]
+			}
+		}
+	}
+
+	if f.low[v] == f.index[v] {
+		var comp []string
+		for {
+			n := len(f.stack) - 1
+			w := f.stack[n]
+			f.stack = f.stack[:n]
+			f.onStack[w] = false
+			comp = append(comp, w)
+			if w == v {
+				break
+			}
+		}
+		f.sccs = append(f.sccs, comp)
+	}
+}
+
+// sccOf indexes the SCCs returned by SCCs() for quick membership lookup.
+func (ig *ImportGraph) sccOf() (compID map[string]int, comps [][]string) {
+	comps = ig.SCCs()
+	compID = make(map[string]int, len(ig.added))
+	for i, comp := range comps {
+		for _, pn := range comp {
+			compID[pn] = i
+		}
+	}
+	return compID, comps
+}
+
+// Cycles returns the non-trivial SCCs, i.e. the actual import cycles in
+// the graph: components with more than one member, plus any single
+// package that imports itself.
+func (ig *ImportGraph) Cycles() [][]string {
+	var out [][]string
+	for _, comp := range ig.SCCs() {
+		if len(comp) > 1 || (len(comp) == 1 && ig.selfImports(comp[0])) {
+			out = append(out, comp)
+		}
+	}
+	return out
+}
+
+func (ig *ImportGraph) selfImports(pn string) bool {
+	for _, pi := range ig.Imports[pn] {
+		if pi == pn {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCycles prints only the import cycles found in the graph, one
+// member path per line, grouped by cycle.
+func (ig *ImportGraph) WriteCycles(w io.Writer, mr matcher) {
+	for i, comp := range ig.Cycles() {
+		var matched []string
+		for _, pn := range comp {
+			if mr.Match(pn) {
+				matched = append(matched, pn)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "cycle %d:\n", i)
+		for _, pn := range matched {
+			fmt.Fprintf(w, "    %s\n", pn)
+		}
+	}
+}
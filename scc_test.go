@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newCycleTestGraph builds a small graph with a 3-node import cycle
+// (a -> b -> c -> a), a package that imports itself (d -> d), and an
+// acyclic package (e) reachable only from a.
+func newCycleTestGraph() *ImportGraph {
+	return &ImportGraph{
+		Imports: map[string][]string{
+			"a": {"b", "e"},
+			"b": {"c"},
+			"c": {"a"},
+			"d": {"d"},
+			"e": nil,
+		},
+		added: []string{"a", "b", "c", "d", "e"},
+	}
+}
+
+// sortedComponents renders each component as its sorted, comma-joined
+// members, then sorts the whole list, so component order (which
+// Tarjan's algorithm does not guarantee to be stable across inputs)
+// doesn't matter for comparison.
+func sortedComponents(comps [][]string) []string {
+	var out []string
+	for _, c := range comps {
+		sorted := append([]string(nil), c...)
+		sort.Strings(sorted)
+		out = append(out, strings.Join(sorted, ","))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestSCCsFindsCycleAndSelfImport(t *testing.T) {
+	ig := newCycleTestGraph()
+	got := sortedComponents(ig.SCCs())
+	want := []string{"a,b,c", "d", "e"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SCCs() = %v, want %v", got, want)
+	}
+}
+
+func TestCyclesExcludesAcyclicSingleton(t *testing.T) {
+	ig := newCycleTestGraph()
+	got := sortedComponents(ig.Cycles())
+	want := []string{"a,b,c", "d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Cycles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zncoder/cli"
+)
+
+// doWhy implements `inspectvendor why`, the analogue of `go mod why`: it
+// reports the shortest import chain from a root package down to a
+// target import path, so a user can see why a vendored package ended
+// up in the tree.
+func doWhy() {
+	byModule := flag.Bool("m", false, "answer at module (vendor-root) granularity instead of package granularity")
+	cli.ParseFlag()
+
+	if flag.NArg() != 1 {
+		log.Fatalf("usage: why [-m] <import path>")
+	}
+	target := flag.Arg(0)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("getwd err=%v", err)
+	}
+
+	// Scan every package in the module, not just the one in wd, so a
+	// target imported only by a non-root package is still found.
+	ig := NewImportGraph(wd, false, []string{"./..."})
+	ig.Scan()
+
+	path := ig.Why(target, *byModule)
+	if path == nil {
+		fmt.Printf("%s is not imported\n", target)
+		return
+	}
+	for _, pn := range path {
+		fmt.Println(pn)
+	}
+}
+
+// Why builds the reverse adjacency map of ig.Imports and walks it
+// backward from target to find the shortest chain of imports from any
+// of the initial `go list` roots down to target. When byModule is set,
+// target and every package along the way are compared at module
+// (vendor-root) granularity, so the chain names which top-level
+// dependency dragged in the target module rather than the exact
+// package. ig.Imports is a map, so both the seed list and each
+// adjacency slice are sorted before traversal: without that, map
+// iteration order would make the choice among equally-short paths vary
+// from run to run of the same graph.
+func (ig *ImportGraph) Why(target string, byModule bool) []string {
+	matches := func(pn string) bool {
+		if byModule {
+			return granularPath(pn) == granularPath(target)
+		}
+		return pn == target
+	}
+
+	reverse := map[string][]string{}
+	for pn, imports := range ig.Imports {
+		for _, pi := range imports {
+			reverse[pi] = append(reverse[pi], pn)
+		}
+	}
+	for pi := range reverse {
+		sort.Strings(reverse[pi])
+	}
+
+	isRoot := make(map[string]bool, len(ig.roots))
+	for _, r := range ig.roots {
+		isRoot[r] = true
+	}
+
+	var seeds []string
+	for pn := range ig.Imports {
+		if matches(pn) {
+			seeds = append(seeds, pn)
+		}
+	}
+	sort.Strings(seeds)
+
+	type node struct {
+		pn   string
+		prev *node
+	}
+	visited := map[string]bool{}
+	var queue []*node
+	// Seed with every package matching target, not just one, so the
+	// result is the true shortest chain even when -m groups several
+	// packages under the same module.
+	for _, pn := range seeds {
+		if !visited[pn] {
+			visited[pn] = true
+			queue = append(queue, &node{pn: pn})
+		}
+	}
+	if len(queue) == 0 {
+		return nil
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		if isRoot[n.pn] {
+			var path []string
+			for x := n; x != nil; x = x.prev {
+				path = append(path, x.pn)
+			}
+			return path
+		}
+
+		for _, pi := range reverse[n.pn] {
+			if visited[pi] {
+				continue
+			}
+			visited[pi] = true
+			queue = append(queue, &node{pn: pi, prev: n})
+		}
+	}
+	return nil
+}
+
+// granularPath reduces an import path to its vendor-root/module
+// granularity, used for -m matching in Why.
+func granularPath(pn string) string {
+	if i := strings.Index(pn, "/vendor/"); i >= 0 {
+		return vendorModulePath(pn[i+len("/vendor/"):])
+	}
+	return vendorModulePath(pn)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhyDeterministicWithTiedPaths(t *testing.T) {
+	// Four equally-short paths reach "target", each via its own root.
+	// Regardless of map iteration order, Why must always return the
+	// same chain on every run.
+	ig := &ImportGraph{
+		Imports: map[string][]string{
+			"root1": {"mid1"}, "mid1": {"target"},
+			"root2": {"mid2"}, "mid2": {"target"},
+			"root3": {"mid3"}, "mid3": {"target"},
+			"root4": {"mid4"}, "mid4": {"target"},
+			"target": nil,
+		},
+		roots: []string{"root1", "root2", "root3", "root4"},
+	}
+
+	want := []string{"root1", "mid1", "target"}
+	for i := 0; i < 30; i++ {
+		got := ig.Why("target", false)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: Why() = %v, want %v", i, got, want)
+		}
+	}
+}